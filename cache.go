@@ -0,0 +1,93 @@
+// Package cache provides a generic cache interface with pluggable
+// adapters (memory, file, and more) selectable by name.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache is the interface implemented by every adapter - MemoryCache,
+// FileCache and future remote backends such as Redis or Memcached - so
+// applications can swap backends without touching call sites. Every
+// operation takes a context.Context so remote adapters can honor
+// cancellation and deadlines and callers can propagate tracing.
+type Cache interface {
+	// Get value by key.
+	Get(ctx context.Context, key string) (interface{}, error)
+	// GetMulti values by multi key
+	GetMulti(ctx context.Context, keys []string) ([]interface{}, error)
+	// Set value by key, with expire time. A bounded adapter with an
+	// AdmissionPolicy may reject the write instead of evicting to make
+	// room, in which case it returns ErrAdmissionRejected and val is
+	// not stored.
+	Set(ctx context.Context, key string, val interface{}, ttl time.Duration) error
+	// SetMulti values by multi key, with expire time.
+	SetMulti(ctx context.Context, values map[string]interface{}, ttl time.Duration) error
+	// Del value by key.
+	Del(ctx context.Context, key string) error
+	// DelMulti values by multi key.
+	DelMulti(ctx context.Context, keys []string) error
+	// Has cache key.
+	Has(ctx context.Context, key string) (bool, error)
+	// Clear all caches.
+	Clear(ctx context.Context) error
+	// Incr increases the int64 value by key, as a counter.
+	Incr(ctx context.Context, key string) (int64, error)
+	// Decr decreases the int64 value by key, as a counter.
+	Decr(ctx context.Context, key string) (int64, error)
+	// Count cache item number.
+	Count() int
+	// LastErr get the last error.
+	LastErr() error
+}
+
+// Factory builds a Cache instance from a JSON config string. Each
+// adapter registers its own Factory via Register, usually from an
+// init() function in the file that implements the adapter.
+type Factory func(config string) (Cache, error)
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   = make(map[string]Factory)
+)
+
+// Register makes a Cache adapter available by the provided name. It
+// panics if Register is called twice with the same name or if factory
+// is nil.
+func Register(name string, factory Factory) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+
+	if factory == nil {
+		panic("cache: Register factory is nil")
+	}
+	if _, ok := adapters[name]; ok {
+		panic("cache: Register called twice for adapter " + name)
+	}
+
+	adapters[name] = factory
+}
+
+// NewCache creates a new Cache instance by adapter name. The config
+// string is adapter-specific, usually a JSON object, and is passed
+// through to the registered Factory unmodified.
+func NewCache(name, config string) (Cache, error) {
+	adaptersMu.RLock()
+	factory, ok := adapters[name]
+	adaptersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("cache: unknown adapter %q (forgotten import?)", name)
+	}
+
+	return factory(config)
+}
+
+// ensure the built-in adapters satisfy Cache.
+var (
+	_ Cache = (*MemoryCache)(nil)
+	_ Cache = (*FileCache)(nil)
+)
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewCacheUnknownAdapter(t *testing.T) {
+	if _, err := NewCache("does-not-exist", ""); err == nil {
+		t.Fatalf("NewCache(unknown) error = nil, want a non-nil error")
+	}
+}
+
+func TestRegisterAndNewCache(t *testing.T) {
+	Register("cache_test-fake", func(config string) (Cache, error) {
+		return NewMemoryCache(), nil
+	})
+
+	c, err := NewCache("cache_test-fake", "")
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+
+	ctx := context.Background()
+	if err = c.Set(ctx, "k", "v", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if val, err := c.Get(ctx, "k"); err != nil || val != "v" {
+		t.Fatalf("Get() = (%v, %v), want (\"v\", nil)", val, err)
+	}
+}
+
+func TestRegisterNilFactoryPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Register(nil factory) did not panic")
+		}
+	}()
+
+	Register("cache_test-nil-factory", nil)
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	Register("cache_test-dup", func(config string) (Cache, error) {
+		return NewMemoryCache(), nil
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Register(duplicate name) did not panic")
+		}
+	}()
+
+	Register("cache_test-dup", func(config string) (Cache, error) {
+		return NewMemoryCache(), nil
+	})
+}
+
+func TestNewCacheMemoryAdapter(t *testing.T) {
+	c, err := NewCache("memory", "")
+	if err != nil {
+		t.Fatalf("NewCache(memory): %v", err)
+	}
+
+	ctx := context.Background()
+	if err = c.Set(ctx, "k", 1, 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if val, err := c.Get(ctx, "k"); err != nil || val != 1 {
+		t.Fatalf("Get() = (%v, %v), want (1, nil)", val, err)
+	}
+}
+
+func TestNewCacheFileAdapter(t *testing.T) {
+	c, err := NewCache("file", "")
+	if err != nil {
+		t.Fatalf("NewCache(file): %v", err)
+	}
+
+	ctx := context.Background()
+	if err = c.Set(ctx, "k", "v", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if val, err := c.Get(ctx, "k"); err != nil || val != "v" {
+		t.Fatalf("Get() = (%v, %v), want (\"v\", nil)", val, err)
+	}
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec encodes and decodes cache values for on-disk storage. Callers
+// pass one to NewFileCache via WithCodec to pick the wire format;
+// JSONCodec is the default, since it round-trips arbitrary structs
+// without any setup from the caller.
+type Codec interface {
+	// Encode serializes v.
+	Encode(v interface{}) ([]byte, error)
+	// Decode deserializes data into v, which must be a pointer.
+	Decode(data []byte, v interface{}) error
+}
+
+// GobCodec encodes values with encoding/gob.
+//
+// CacheItem.Val is stored as interface{}, and gob requires every
+// concrete type that ever flows through an interface{} to be
+// registered with gob.Register before it is encoded or decoded -
+// otherwise Encode/Decode fail with "gob: type not registered for
+// interface". Prefer JSONCodec unless you have already registered
+// every type you cache.
+type GobCodec struct{}
+
+// Encode implements Codec.
+func (GobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode implements Codec.
+func (GobCodec) Decode(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// JSONCodec encodes values with encoding/json.
+type JSONCodec struct{}
+
+// Encode implements Codec.
+func (JSONCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode implements Codec.
+func (JSONCodec) Decode(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// MsgpackCodec encodes values with github.com/vmihailenco/msgpack.
+type MsgpackCodec struct{}
+
+// Encode implements Codec.
+func (MsgpackCodec) Encode(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Decode implements Codec.
+func (MsgpackCodec) Decode(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+type codecTestStruct struct {
+	Name string
+	N    int
+}
+
+func TestGobCodecRoundTrip(t *testing.T) {
+	gob.Register(codecTestStruct{})
+
+	want := &CacheItem{Val: codecTestStruct{Name: "a", N: 1}}
+	testCodecRoundTrip(t, GobCodec{}, want, want.Val)
+}
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	// json round-trips a struct Val back as a map[string]interface{},
+	// unlike GobCodec which preserves the concrete type.
+	want := &CacheItem{Val: codecTestStruct{Name: "a", N: 1}}
+	wantVal := map[string]interface{}{"Name": "a", "N": float64(1)}
+	testCodecRoundTrip(t, JSONCodec{}, want, wantVal)
+}
+
+func testCodecRoundTrip(t *testing.T, codec Codec, item *CacheItem, wantVal interface{}) {
+	t.Helper()
+
+	bs, err := codec.Encode(item)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	got := &CacheItem{}
+	if err = codec.Decode(bs, got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if got.Exp != item.Exp {
+		t.Fatalf("decoded Exp = %v, want %v", got.Exp, item.Exp)
+	}
+	if !reflect.DeepEqual(got.Val, wantVal) {
+		t.Fatalf("decoded Val = %#v, want %#v", got.Val, wantVal)
+	}
+}
@@ -2,15 +2,58 @@
 package cache
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"time"
 )
 
+func init() {
+	Register("file", newFileCacheAdapter)
+}
+
+// fileCacheConfig is the JSON shape accepted by NewCache("file", config).
+type fileCacheConfig struct {
+	CacheDir    string `json:"cacheDir"`
+	Prefix      string `json:"prefix"`
+	SecurityKey string `json:"securityKey"`
+	// GCInterval, if set, is parsed with time.ParseDuration and passed
+	// to StartAndGC.
+	GCInterval string `json:"gcInterval"`
+}
+
+// newFileCacheAdapter is the Factory registered for the "file" adapter
+// name.
+func newFileCacheAdapter(config string) (Cache, error) {
+	cfg := &fileCacheConfig{}
+	if config != "" {
+		if err := json.Unmarshal([]byte(config), cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	c := NewFileCache(cfg.CacheDir, WithPrefix(cfg.Prefix), WithSecurityKey(cfg.SecurityKey))
+
+	if cfg.GCInterval != "" {
+		interval, err := time.ParseDuration(cfg.GCInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = c.StartAndGC(interval); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
 // FileCache definition.
 type FileCache struct {
 	// caches in memory
@@ -21,83 +64,175 @@ type FileCache struct {
 	prefix string
 	// security key for generate cache file name.
 	securityKey string
+	// stopFileGC, if non-nil, signals the background file GC goroutine
+	// to stop
+	stopFileGC chan struct{}
+	// loaders coalesces concurrent GetOrLoad misses for the same key
+	loaders singleflightGroup
+	// codec (de)serializes cache items for on-disk storage
+	codec Codec
+}
+
+// FileCacheOption configures a FileCache created by NewFileCache.
+type FileCacheOption func(*FileCache)
+
+// WithPrefix sets the cache file name prefix.
+func WithPrefix(prefix string) FileCacheOption {
+	return func(c *FileCache) { c.prefix = prefix }
+}
+
+// WithSecurityKey sets the key mixed into generated cache file names.
+func WithSecurityKey(key string) FileCacheOption {
+	return func(c *FileCache) { c.securityKey = key }
+}
+
+// WithCodec sets the Codec used to serialize values to the cache
+// file. The default is JSONCodec; pass GobCodec only if you have
+// already gob.Register'd every type you cache.
+func WithCodec(codec Codec) FileCacheOption {
+	return func(c *FileCache) { c.codec = codec }
 }
 
 // New a FileCache instance
-func NewFileCache(dir string, pfxAndKey ...string) *FileCache {
+func NewFileCache(dir string, opts ...FileCacheOption) *FileCache {
 	if dir == "" { // empty, use system tmp dir
 		dir = os.TempDir()
 	}
 
 	c := &FileCache{
 		cacheDir: dir,
+		codec:    JSONCodec{},
 		// init a memory cache.
 		MemoryCache: MemoryCache{caches: make(map[string]*CacheItem)},
 	}
 
-	if ln := len(pfxAndKey); ln > 0 {
-		c.prefix = pfxAndKey[0]
-
-		if ln > 1 {
-			c.securityKey = pfxAndKey[1]
-		}
+	for _, opt := range opts {
+		opt(c)
 	}
 
 	return c
 }
 
-// Has cache key.
-// TODO decode value, and check expire time
-func (c *FileCache) Has(key string) bool {
-	if c.MemoryCache.Has(key) {
-		return true
+// Has cache key. An on-disk entry is decoded to check Exp, since a
+// stale cache file must not count as present.
+func (c *FileCache) Has(ctx context.Context, key string) (bool, error) {
+	if ok, err := c.MemoryCache.Has(ctx, key); ok || err != nil {
+		return ok, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	bs, err := ioutil.ReadFile(c.GetFilename(key))
+	if err != nil {
+		return false, nil
+	}
+
+	item := &CacheItem{}
+	if err = c.codec.Decode(bs, item); err != nil {
+		c.setLastErr(err)
+		return false, nil
 	}
 
-	path := c.GetFilename(key)
-	return fileExists(path)
+	return item.Exp == 0 || item.Exp > time.Now().Unix(), nil
 }
 
-func (c *FileCache) Get(key string) interface{} {
+// Get cache value by key.
+func (c *FileCache) Get(ctx context.Context, key string) (interface{}, error) {
 	// read cache from memory
-	if val := c.MemoryCache.Get(key); val != nil {
-		return val
+	val, err := c.MemoryCache.Get(ctx, key)
+	if err != nil || val != nil {
+		return val, err
 	}
 
-	c.lock.RLock()
-	defer c.lock.RUnlock()
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	// read cache from file
+	// read cache from file; ReadFile and Decode touch no shared state,
+	// so they run without holding c.lock.
 	bs, err := ioutil.ReadFile(c.GetFilename(key))
 	if err != nil {
-		c.lastErr = err
-		return nil
+		c.setLastErr(err)
+		return nil, nil
 	}
 
 	item := &CacheItem{}
-	if err = Unmarshal(bs, item); err != nil {
-		c.lastErr = err
-		return nil
+	if err = c.codec.Decode(bs, item); err != nil {
+		c.setLastErr(err)
+		return nil, err
 	}
 
 	// check expire time
 	if item.Exp == 0 || item.Exp > time.Now().Unix() {
+		c.lock.Lock()
 		c.caches[key] = item // save to memory.
-		return item.Val
+		c.lock.Unlock()
+		return item.Val, nil
 	}
 
-	// has been expired. delete it.
-	c.Del(key)
-	return nil
+	// has been expired. delete it. Del takes its own lock, so it must
+	// run without c.lock held.
+	return nil, c.Del(ctx, key)
 }
 
-func (c *FileCache) Set(key string, val interface{}, ttl time.Duration) (err error) {
-	if err = c.MemoryCache.Set(key, val, ttl); err != nil {
+// GetInto decodes the cached value for key into dst, which must be a
+// non-nil pointer, instead of handing back an interface{} for the
+// caller to type-assert. It reports whether key was found.
+func (c *FileCache) GetInto(ctx context.Context, key string, dst interface{}) (bool, error) {
+	if val, err := c.MemoryCache.Get(ctx, key); err != nil {
+		return false, err
+	} else if val != nil {
+		return true, assignInto(val, dst)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	// ReadFile and Decode touch no shared state, so they run without
+	// holding c.lock; only Del below needs it, and takes its own.
+	bs, err := ioutil.ReadFile(c.GetFilename(key))
+	if err != nil {
+		c.setLastErr(err)
+		return false, nil
+	}
+
+	item := &CacheItem{}
+	if err = c.codec.Decode(bs, item); err != nil {
+		c.setLastErr(err)
+		return false, err
+	}
+
+	if item.Exp != 0 && item.Exp <= time.Now().Unix() {
+		return false, c.Del(ctx, key)
+	}
+
+	return true, assignInto(item.Val, dst)
+}
+
+// setLastErr records err under c.lock.
+func (c *FileCache) setLastErr(err error) {
+	c.lock.Lock()
+	c.lastErr = err
+	c.lock.Unlock()
+}
+
+// Set cache value by key.
+func (c *FileCache) Set(ctx context.Context, key string, val interface{}, ttl time.Duration) (err error) {
+	if err = c.MemoryCache.Set(ctx, key, val, ttl); err != nil {
 		c.lastErr = err
 		return
 	}
 
-	// cache item data to file
-	bs, err := Marshal(c.caches[key])
+	return c.persist(key)
+}
+
+// persist writes the in-memory CacheItem for key to its cache file,
+// serialized with c.codec.
+func (c *FileCache) persist(key string) (err error) {
+	bs, err := c.codec.Encode(c.caches[key])
 	if err != nil {
 		c.lastErr = err
 		return
@@ -126,9 +261,11 @@ func (c *FileCache) Set(key string, val interface{}, ttl time.Duration) (err err
 	return
 }
 
-// Del value by key
-func (c *FileCache) Del(key string) error {
-	c.MemoryCache.Del(key)
+// Del value by key.
+func (c *FileCache) Del(ctx context.Context, key string) error {
+	if err := c.MemoryCache.Del(ctx, key); err != nil {
+		return err
+	}
 
 	file := c.GetFilename(key)
 	if fileExists(file) {
@@ -138,20 +275,25 @@ func (c *FileCache) Del(key string) error {
 	return nil
 }
 
-// GetMulti values by multi key
-func (c *FileCache) GetMulti(keys []string) []interface{} {
+// GetMulti values by multi key.
+func (c *FileCache) GetMulti(ctx context.Context, keys []string) ([]interface{}, error) {
 	var values []interface{}
 	for _, key := range keys {
-		values = append(values, c.Get(key))
+		val, err := c.Get(ctx, key)
+		if err != nil {
+			return values, err
+		}
+
+		values = append(values, val)
 	}
 
-	return values
+	return values, nil
 }
 
-// SetMulti values by multi key
-func (c *FileCache) SetMulti(values map[string]interface{}, ttl time.Duration) (err error) {
+// SetMulti values by multi key.
+func (c *FileCache) SetMulti(ctx context.Context, values map[string]interface{}, ttl time.Duration) (err error) {
 	for key, val := range values {
-		if err = c.Set(key, val, ttl); err != nil {
+		if err = c.Set(ctx, key, val, ttl); err != nil {
 			return
 		}
 	}
@@ -159,16 +301,22 @@ func (c *FileCache) SetMulti(values map[string]interface{}, ttl time.Duration) (
 	return
 }
 
-// DelMulti values by multi key
-func (c *FileCache) DelMulti(keys []string) error {
+// DelMulti values by multi key.
+func (c *FileCache) DelMulti(ctx context.Context, keys []string) error {
 	for _, key := range keys {
-		c.Del(key)
+		if err := c.Del(ctx, key); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// Clear caches and files
-func (c *FileCache) Clear() error {
+// Clear caches and files.
+func (c *FileCache) Clear(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	for key := range c.caches {
 		file := c.GetFilename(key)
 
@@ -180,11 +328,154 @@ func (c *FileCache) Clear() error {
 		}
 	}
 
-	c.caches = nil
+	if err := c.MemoryCache.Clear(ctx); err != nil {
+		return err
+	}
+
 	// clear cache files
 	return os.RemoveAll(c.cacheDir)
 }
 
+// Incr increases the int64 value by key, as a counter, and persists
+// the new value to the cache file.
+func (c *FileCache) Incr(ctx context.Context, key string) (int64, error) {
+	return c.IncrBy(ctx, key, 1)
+}
+
+// Decr decreases the int64 value by key, as a counter, and persists
+// the new value to the cache file.
+func (c *FileCache) Decr(ctx context.Context, key string) (int64, error) {
+	return c.IncrBy(ctx, key, -1)
+}
+
+// IncrBy adds delta to the int64 value by key, as a counter, and
+// persists the new value to the cache file.
+func (c *FileCache) IncrBy(ctx context.Context, key string, delta int64) (int64, error) {
+	n, err := c.MemoryCache.IncrBy(ctx, key, delta)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = c.persist(key); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// GetOrLoad returns the cached value for key if present in memory or
+// on disk - including a cached nil, which Has reports as present even
+// though Get alone cannot tell it apart from a miss - otherwise it
+// calls loader exactly once even under concurrent misses for the same
+// key, persists the result to disk for ttl, and returns it. This is
+// essential when FileCache fronts an expensive computation, since
+// otherwise N concurrent misses would all invoke loader and race to
+// write the same cache file.
+func (c *FileCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if ok, err := c.Has(ctx, key); err != nil {
+		return nil, err
+	} else if ok {
+		return c.Get(ctx, key)
+	}
+
+	return c.loaders.Do(key, func() (interface{}, error) {
+		// another goroutine may have populated the cache while we were
+		// waiting to become the leader for key.
+		if ok, err := c.Has(ctx, key); err != nil {
+			return nil, err
+		} else if ok {
+			return c.Get(ctx, key)
+		}
+
+		val, err := loader(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = c.Set(ctx, key, val, ttl); err != nil {
+			return nil, err
+		}
+
+		return val, nil
+	})
+}
+
+// StartAndGC starts a background goroutine that removes expired
+// entries from memory every interval, and a second goroutine that
+// walks cacheDir every interval unlinking expired cache files. Call
+// Stop to terminate both.
+func (c *FileCache) StartAndGC(interval time.Duration) error {
+	if err := c.MemoryCache.StartAndGC(interval); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	if c.stopFileGC != nil {
+		c.lock.Unlock()
+		return fmt.Errorf("cache: GC already started")
+	}
+	stop := make(chan struct{})
+	c.stopFileGC = stop
+	c.lock.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.gcFiles()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop terminates the background GC goroutines started by StartAndGC.
+func (c *FileCache) Stop() {
+	c.MemoryCache.Stop()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.stopFileGC != nil {
+		close(c.stopFileGC)
+		c.stopFileGC = nil
+	}
+}
+
+// gcFiles walks cacheDir, unlinking any cache file whose CacheItem
+// header has expired.
+func (c *FileCache) gcFiles() {
+	now := time.Now().Unix()
+
+	_ = filepath.Walk(c.cacheDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		bs, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		item := &CacheItem{}
+		if err = c.codec.Decode(bs, item); err != nil {
+			return nil
+		}
+
+		if item.Exp != 0 && item.Exp <= now {
+			os.Remove(path)
+		}
+
+		return nil
+	})
+}
+
 // GetFilename cache file name build
 func (c *FileCache) GetFilename(key string) string {
 	h := md5.New()
@@ -207,4 +498,28 @@ func fileExists(name string) bool {
 		}
 	}
 	return true
+}
+
+// assignInto copies val into dst, which must be a non-nil pointer to
+// a type val is assignable to.
+func assignInto(val interface{}, dst interface{}) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("cache: GetInto requires a non-nil pointer, got %T", dst)
+	}
+
+	if val == nil {
+		// a cached nil (see GetOrLoad) has no type to assign; leave dst
+		// at its zero value instead of calling reflect.ValueOf(nil).Type().
+		dv.Elem().Set(reflect.Zero(dv.Elem().Type()))
+		return nil
+	}
+
+	vv := reflect.ValueOf(val)
+	if !vv.Type().AssignableTo(dv.Elem().Type()) {
+		return fmt.Errorf("cache: cannot assign cached %T into %T", val, dst)
+	}
+
+	dv.Elem().Set(vv)
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func newTestFileCache(t *testing.T) *FileCache {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "cache-filecache-test")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return NewFileCache(dir)
+}
+
+func TestFileCacheGetIntoFromMemory(t *testing.T) {
+	ctx := context.Background()
+	c := newTestFileCache(t)
+
+	if err := c.Set(ctx, "key", "value", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	var dst string
+	ok, err := c.GetInto(ctx, "key", &dst)
+	if err != nil {
+		t.Fatalf("GetInto: %v", err)
+	}
+	if !ok {
+		t.Fatalf("GetInto() found = false, want true")
+	}
+	if dst != "value" {
+		t.Fatalf("dst = %q, want %q", dst, "value")
+	}
+}
+
+func TestFileCacheGetIntoFromDisk(t *testing.T) {
+	ctx := context.Background()
+	c := newTestFileCache(t)
+
+	if err := c.Set(ctx, "key", "value", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	// evict the in-memory copy so GetInto must decode from disk.
+	if err := c.MemoryCache.Clear(ctx); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+
+	var dst string
+	ok, err := c.GetInto(ctx, "key", &dst)
+	if err != nil {
+		t.Fatalf("GetInto: %v", err)
+	}
+	if !ok {
+		t.Fatalf("GetInto() found = false, want true")
+	}
+	if dst != "value" {
+		t.Fatalf("dst = %q, want %q: disk-read path must copy into dst", dst, "value")
+	}
+}
+
+func TestFileCacheGetIntoMiss(t *testing.T) {
+	ctx := context.Background()
+	c := newTestFileCache(t)
+
+	var dst string
+	ok, err := c.GetInto(ctx, "missing", &dst)
+	if err != nil {
+		t.Fatalf("GetInto: %v", err)
+	}
+	if ok {
+		t.Fatalf("GetInto() found = true, want false")
+	}
+}
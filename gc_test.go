@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheStartAndGCRemovesExpired(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	if err := c.Set(ctx, "short", "v", 10*time.Millisecond); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Set(ctx, "long", "v", time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if err := c.StartAndGC(20 * time.Millisecond); err != nil {
+		t.Fatalf("StartAndGC: %v", err)
+	}
+	defer c.Stop()
+
+	time.Sleep(80 * time.Millisecond)
+
+	if n := c.Count(); n != 1 {
+		t.Fatalf("Count() = %d, want 1 after GC sweep", n)
+	}
+	if ok, _ := c.Has(ctx, "long"); !ok {
+		t.Fatalf("Has(long) = false, want true: unexpired entries must survive GC")
+	}
+}
+
+func TestMemoryCacheStartAndGCTwiceErrors(t *testing.T) {
+	c := NewMemoryCache()
+
+	if err := c.StartAndGC(time.Hour); err != nil {
+		t.Fatalf("StartAndGC: %v", err)
+	}
+	defer c.Stop()
+
+	if err := c.StartAndGC(time.Hour); err == nil {
+		t.Fatalf("second StartAndGC error = nil, want a non-nil error")
+	}
+}
+
+func TestMemoryCacheStartAndGCRejectsNonPositiveInterval(t *testing.T) {
+	c := NewMemoryCache()
+	if err := c.StartAndGC(0); err == nil {
+		t.Fatalf("StartAndGC(0) error = nil, want a non-nil error")
+	}
+}
+
+func TestFileCacheStartAndGCRemovesExpiredFiles(t *testing.T) {
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "cache-gc-test")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	c := NewFileCache(dir)
+	if err = c.Set(ctx, "short", "v", 10*time.Millisecond); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	path := c.GetFilename("short")
+	if !fileExists(path) {
+		t.Fatalf("cache file %s does not exist after Set", path)
+	}
+
+	if err = c.StartAndGC(20 * time.Millisecond); err != nil {
+		t.Fatalf("StartAndGC: %v", err)
+	}
+	defer c.Stop()
+
+	time.Sleep(80 * time.Millisecond)
+
+	if fileExists(path) {
+		t.Fatalf("cache file %s still exists after GC swept its expired entry", path)
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errTestLoader = errors.New("test: loader failed")
+
+func TestMemoryCacheGetOrLoadCachesNil(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	var calls int64
+
+	loader := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		return nil, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		val, err := c.GetOrLoad(ctx, "key", 0, loader)
+		if err != nil {
+			t.Fatalf("GetOrLoad: %v", err)
+		}
+		if val != nil {
+			t.Fatalf("GetOrLoad() = %v, want nil", val)
+		}
+	}
+
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("loader called %d times, want 1: a cached nil must still be a hit", got)
+	}
+}
+
+func TestMemoryCacheGetOrLoadCoalescesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	var calls int64
+	var wg sync.WaitGroup
+
+	loader := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(5 * time.Millisecond)
+		return "loaded", nil
+	}
+
+	results := make([]interface{}, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			val, err := c.GetOrLoad(ctx, "key", time.Minute, loader)
+			if err != nil {
+				t.Errorf("GetOrLoad: %v", err)
+			}
+			results[i] = val
+		}(i)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("loader called %d times, want 1", got)
+	}
+	for i, v := range results {
+		if v != "loaded" {
+			t.Fatalf("results[%d] = %v, want \"loaded\"", i, v)
+		}
+	}
+}
+
+func TestMemoryCacheGetOrLoadPropagatesLoaderError(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	wantErr := errTestLoader
+
+	_, err := c.GetOrLoad(ctx, "key", 0, func(ctx context.Context) (interface{}, error) {
+		return nil, wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("GetOrLoad error = %v, want %v", err, wantErr)
+	}
+
+	if ok, _ := c.Has(ctx, "key"); ok {
+		t.Fatalf("Has(key) = true, want false: a failed load must not be cached")
+	}
+}
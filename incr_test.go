@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestMemoryCacheIncrCreatesCounter(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	n, err := c.Incr(ctx, "counter")
+	if err != nil {
+		t.Fatalf("Incr: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Incr() = %d, want 1", n)
+	}
+
+	n, err = c.IncrBy(ctx, "counter", 4)
+	if err != nil {
+		t.Fatalf("IncrBy: %v", err)
+	}
+	if n != 5 {
+		t.Fatalf("IncrBy() = %d, want 5", n)
+	}
+
+	n, err = c.Decr(ctx, "counter")
+	if err != nil {
+		t.Fatalf("Decr: %v", err)
+	}
+	if n != 4 {
+		t.Fatalf("Decr() = %d, want 4", n)
+	}
+}
+
+func TestMemoryCacheIncrNonIntegerErrors(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	if err := c.Set(ctx, "key", "not a number", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if _, err := c.Incr(ctx, "key"); err == nil {
+		t.Fatalf("Incr() on a non-integer value: error = nil, want a non-nil error")
+	}
+}
+
+// TestMemoryCacheIncrRespectsBound reproduces a bug where Incr created
+// counters directly in c.caches, bypassing the maxEntries bound and
+// EvictionPolicy bookkeeping that Set goes through.
+func TestMemoryCacheIncrRespectsBound(t *testing.T) {
+	ctx := context.Background()
+	c := NewBoundedMemoryCache(2, NewLRUPolicy())
+
+	for i := 0; i < 10; i++ {
+		if _, err := c.Incr(ctx, string(rune('a'+i))); err != nil {
+			t.Fatalf("Incr: %v", err)
+		}
+	}
+
+	if n := c.Count(); n > 2 {
+		t.Fatalf("Count() = %d, want <= 2: Incr must respect maxEntries", n)
+	}
+}
+
+// TestFileCacheIncrAfterJSONRoundTrip reproduces a bug where a counter
+// read back through JSONCodec - which decodes numbers as float64 -
+// failed Incr with "value is not an integer counter".
+func TestFileCacheIncrAfterJSONRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "cache-incr-test")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	fc1 := NewFileCache(dir)
+	if err = fc1.Set(ctx, "counter", 5, 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	fc2 := NewFileCache(dir)
+	// force the on-disk JSON value into fc2's memory tier, where it
+	// comes back as float64.
+	if _, err = fc2.Get(ctx, "counter"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	n, err := fc2.Incr(ctx, "counter")
+	if err != nil {
+		t.Fatalf("Incr: %v", err)
+	}
+	if n != 6 {
+		t.Fatalf("Incr() = %d, want 6", n)
+	}
+}
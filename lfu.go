@@ -0,0 +1,139 @@
+package cache
+
+import "sync"
+
+// LFUPolicy is an EvictionPolicy that evicts the least frequently
+// used key first. Keys are grouped into per-frequency buckets so Add,
+// Touch and Remove run in O(1), the same approach used by the classic
+// O(1) LFU cache algorithm. Victim is O(1) too, except after Remove has
+// emptied the lowest-frequency bucket, when it scans all buckets once
+// to find the new minimum.
+type LFUPolicy struct {
+	mu      sync.Mutex
+	freq    map[string]int64
+	buckets map[int64]map[string]struct{}
+	minFreq int64
+}
+
+// NewLFUPolicy creates an empty LFUPolicy.
+func NewLFUPolicy() *LFUPolicy {
+	return &LFUPolicy{
+		freq:    make(map[string]int64),
+		buckets: make(map[int64]map[string]struct{}),
+	}
+}
+
+// Add records the insertion of a new key at frequency 1.
+func (p *LFUPolicy) Add(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.freq[key]; ok {
+		p.bump(key)
+		return
+	}
+
+	p.freq[key] = 1
+	p.addToBucket(1, key)
+	p.minFreq = 1
+}
+
+// Touch increases key's access frequency by one.
+func (p *LFUPolicy) Touch(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.bump(key)
+}
+
+// Remove forgets key.
+func (p *LFUPolicy) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n, ok := p.freq[key]
+	if !ok {
+		return
+	}
+
+	p.removeFromBucket(n, key)
+	delete(p.freq, key)
+}
+
+// Victim returns a key at the lowest recorded frequency, or "" if the
+// policy holds no keys.
+func (p *LFUPolicy) Victim() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, ok := p.buckets[p.minFreq]
+	if !ok || len(b) == 0 {
+		min, found := int64(0), false
+		for n, keys := range p.buckets {
+			if len(keys) == 0 {
+				continue
+			}
+			if !found || n < min {
+				min, found = n, true
+			}
+		}
+
+		if !found {
+			return ""
+		}
+
+		p.minFreq = min
+		b = p.buckets[min]
+	}
+
+	for key := range b {
+		return key
+	}
+
+	return ""
+}
+
+// bump moves key from its current frequency bucket to the next one.
+// Caller must hold p.mu.
+func (p *LFUPolicy) bump(key string) {
+	n, ok := p.freq[key]
+	if !ok {
+		return
+	}
+
+	p.removeFromBucket(n, key)
+	old := n
+	n++
+	p.freq[key] = n
+	p.addToBucket(n, key)
+
+	// only move minFreq up if key has just emptied the minFreq bucket;
+	// a bucket that Remove emptied is left for Victim to rescan.
+	if old == p.minFreq {
+		if _, ok := p.buckets[old]; !ok {
+			p.minFreq = n
+		}
+	}
+}
+
+// addToBucket adds key to the bucket for frequency n. Caller must hold p.mu.
+func (p *LFUPolicy) addToBucket(n int64, key string) {
+	b, ok := p.buckets[n]
+	if !ok {
+		b = make(map[string]struct{})
+		p.buckets[n] = b
+	}
+
+	b[key] = struct{}{}
+}
+
+// removeFromBucket removes key from the bucket for frequency n.
+// Caller must hold p.mu.
+func (p *LFUPolicy) removeFromBucket(n int64, key string) {
+	if b, ok := p.buckets[n]; ok {
+		delete(b, key)
+		if len(b) == 0 {
+			delete(p.buckets, n)
+		}
+	}
+}
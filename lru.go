@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRUPolicy is an EvictionPolicy that evicts the least recently used
+// key first. It is backed by a doubly linked list plus an index map,
+// both guarded by a mutex.
+type LRUPolicy struct {
+	mu    sync.Mutex
+	ll    *list.List
+	elems map[string]*list.Element
+}
+
+// NewLRUPolicy creates an empty LRUPolicy.
+func NewLRUPolicy() *LRUPolicy {
+	return &LRUPolicy{
+		ll:    list.New(),
+		elems: make(map[string]*list.Element),
+	}
+}
+
+// Add records key as the most recently used.
+func (p *LRUPolicy) Add(key string) {
+	p.Touch(key)
+}
+
+// Touch moves key to the most recently used position.
+func (p *LRUPolicy) Touch(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if el, ok := p.elems[key]; ok {
+		p.ll.MoveToFront(el)
+		return
+	}
+
+	p.elems[key] = p.ll.PushFront(key)
+}
+
+// Remove forgets key.
+func (p *LRUPolicy) Remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if el, ok := p.elems[key]; ok {
+		p.ll.Remove(el)
+		delete(p.elems, key)
+	}
+}
+
+// Victim returns the least recently used key, or "" if the policy
+// holds no keys.
+func (p *LRUPolicy) Victim() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	el := p.ll.Back()
+	if el == nil {
+		return ""
+	}
+
+	return el.Value.(string)
+}
@@ -2,10 +2,58 @@
 package cache
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrAdmissionRejected is returned by Set when the cache is full and
+// its AdmissionPolicy (e.g. TinyLFUPolicy) rejects the new key in
+// favor of keeping the current Victim. The value was not stored.
+var ErrAdmissionRejected = errors.New("cache: entry rejected by admission policy")
+
+func init() {
+	Register("memory", newMemoryCacheAdapter)
+}
+
+// memoryCacheConfig is the JSON shape accepted by NewCache("memory", config).
+type memoryCacheConfig struct {
+	// GCInterval, if set, is parsed with time.ParseDuration and passed
+	// to StartAndGC.
+	GCInterval string `json:"gcInterval"`
+}
+
+// newMemoryCacheAdapter is the Factory registered for the "memory"
+// adapter name.
+func newMemoryCacheAdapter(config string) (Cache, error) {
+	c := NewMemoryCache()
+	if config == "" {
+		return c, nil
+	}
+
+	cfg := &memoryCacheConfig{}
+	if err := json.Unmarshal([]byte(config), cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.GCInterval != "" {
+		interval, err := time.ParseDuration(cfg.GCInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = c.StartAndGC(interval); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
 // MemoryCache definition.
 type MemoryCache struct {
 	// locker
@@ -14,6 +62,23 @@ type MemoryCache struct {
 	caches map[string]*CacheItem
 	// last error
 	lastErr error
+	// stopGC, if non-nil, signals the background GC goroutine to stop
+	stopGC chan struct{}
+	// maxEntries bounds the cache size; 0 means unbounded
+	maxEntries int
+	// policy picks the eviction victim once maxEntries is reached
+	policy EvictionPolicy
+	// hit/miss/eviction counters, reported via Stats
+	hits, misses, evictions int64
+	// loaders coalesces concurrent GetOrLoad misses for the same key
+	loaders singleflightGroup
+}
+
+// Stats holds hit/miss/eviction counters for a MemoryCache.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
 }
 
 // CacheItem for memory cache
@@ -31,37 +96,84 @@ func NewMemoryCache() *MemoryCache {
 	}
 }
 
+// NewBoundedMemoryCache creates a memory cache instance that evicts
+// entries once more than maxEntries are stored. If policy is nil,
+// LRUPolicy is used. If policy also implements AdmissionPolicy (e.g.
+// TinyLFUPolicy), Set can reject a new key instead of evicting, in
+// which case it returns ErrAdmissionRejected.
+func NewBoundedMemoryCache(maxEntries int, policy EvictionPolicy) *MemoryCache {
+	if policy == nil {
+		policy = NewLRUPolicy()
+	}
+
+	return &MemoryCache{
+		caches:     make(map[string]*CacheItem),
+		maxEntries: maxEntries,
+		policy:     policy,
+	}
+}
+
 // NewCacheItem create
 func NewCacheItem(val interface{}) *CacheItem {
 	return &CacheItem{Val: val}
 }
 
 // Has cache key
-func (c *MemoryCache) Has(key string) bool {
+func (c *MemoryCache) Has(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	_, ok := c.caches[key]
-	return ok
+	return ok, nil
 }
 
 // Get cache value by key
-func (c *MemoryCache) Get(key string) interface{} {
+func (c *MemoryCache) Get(ctx context.Context, key string) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	c.lock.RLock()
-	defer c.lock.RUnlock()
+	item, ok := c.caches[key]
+	expired := ok && item.Exp != 0 && item.Exp <= time.Now().Unix()
+	var val interface{}
+	if ok && !expired {
+		val = item.Val
+	}
+	c.lock.RUnlock()
 
-	if item, ok := c.caches[key]; ok {
-		// check expire time
-		if item.Exp == 0 || item.Exp > time.Now().Unix() {
-			return item.Val
+	switch {
+	case ok && !expired:
+		atomic.AddInt64(&c.hits, 1)
+		if c.policy != nil {
+			c.policy.Touch(key)
 		}
 
-		// has been expired. delete it.
-		c.Del(key)
+		return val, nil
+	case ok && expired:
+		// has been expired. delete it. Del takes its own lock, so it
+		// must run after the RLock above has been released.
+		atomic.AddInt64(&c.misses, 1)
+		return nil, c.Del(ctx, key)
+	default:
+		atomic.AddInt64(&c.misses, 1)
+		return nil, nil
 	}
-
-	return nil
 }
 
-// Set cache value by key
-func (c *MemoryCache) Set(key string, val interface{}, ttl time.Duration) (err error) {
+// Set cache value by key. If the cache is bounded and full, and its
+// AdmissionPolicy rejects key in favor of keeping the current Victim,
+// Set leaves the existing entries untouched and returns
+// ErrAdmissionRejected.
+func (c *MemoryCache) Set(ctx context.Context, key string, val interface{}, ttl time.Duration) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
@@ -70,36 +182,83 @@ func (c *MemoryCache) Set(key string, val interface{}, ttl time.Duration) (err e
 		item.Exp = time.Now().Unix() + int64(ttl/time.Second)
 	}
 
+	if _, exists := c.caches[key]; !exists && c.maxEntries > 0 && len(c.caches) >= c.maxEntries {
+		if !c.evict(key) {
+			// candidate rejected by the admission filter; keep the
+			// existing entries as-is.
+			return ErrAdmissionRejected
+		}
+	}
+
 	c.caches[key] = item
+	if c.policy != nil {
+		c.policy.Add(key)
+	}
+
 	return
 }
 
+// evict makes room for candidate by evicting c.policy's Victim.
+// Reports false if an AdmissionPolicy rejected candidate instead.
+// Caller must hold c.lock.
+func (c *MemoryCache) evict(candidate string) bool {
+	if c.policy == nil {
+		return true
+	}
+
+	victim := c.policy.Victim()
+	if victim == "" {
+		return true
+	}
+
+	if admission, ok := c.policy.(AdmissionPolicy); ok && !admission.Admit(candidate, victim) {
+		return false
+	}
+
+	delete(c.caches, victim)
+	c.policy.Remove(victim)
+	atomic.AddInt64(&c.evictions, 1)
+	return true
+}
+
 // Del cache by key
-func (c *MemoryCache) Del(key string) error {
-	// c.lock.Lock()
-	// defer c.lock.Unlock()
+func (c *MemoryCache) Del(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
 	if _, ok := c.caches[key]; ok {
 		delete(c.caches, key)
+		if c.policy != nil {
+			c.policy.Remove(key)
+		}
 	}
 
 	return nil
 }
 
 // GetMulti values by multi key
-func (c *MemoryCache) GetMulti(keys []string) []interface{} {
+func (c *MemoryCache) GetMulti(ctx context.Context, keys []string) ([]interface{}, error) {
 	var values []interface{}
 	for _, key := range keys {
-		values = append(values, c.Get(key))
+		val, err := c.Get(ctx, key)
+		if err != nil {
+			return values, err
+		}
+
+		values = append(values, val)
 	}
 
-	return values
+	return values, nil
 }
 
 // SetMulti values by multi key
-func (c *MemoryCache) SetMulti(values map[string]interface{}, ttl time.Duration) (err error) {
+func (c *MemoryCache) SetMulti(ctx context.Context, values map[string]interface{}, ttl time.Duration) (err error) {
 	for key, val := range values {
-		if err = c.Set(key, val, ttl); err != nil {
+		if err = c.Set(ctx, key, val, ttl); err != nil {
 			return
 		}
 	}
@@ -108,25 +267,232 @@ func (c *MemoryCache) SetMulti(values map[string]interface{}, ttl time.Duration)
 }
 
 // DelMulti values by multi key
-func (c *MemoryCache) DelMulti(keys []string) error {
+func (c *MemoryCache) DelMulti(ctx context.Context, keys []string) error {
 	for _, key := range keys {
-		c.Del(key)
+		if err := c.Del(ctx, key); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 // Clear all caches
-func (c *MemoryCache) Clear() error {
-	c.caches = nil
+func (c *MemoryCache) Clear(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.policy != nil {
+		for key := range c.caches {
+			c.policy.Remove(key)
+		}
+	}
+
+	c.caches = make(map[string]*CacheItem)
 	return nil
 }
 
+// Incr increases the int64 value by key, as a counter. If the key does
+// not exist it is created with value 1 and no expiration.
+func (c *MemoryCache) Incr(ctx context.Context, key string) (int64, error) {
+	return c.IncrBy(ctx, key, 1)
+}
+
+// Decr decreases the int64 value by key, as a counter.
+func (c *MemoryCache) Decr(ctx context.Context, key string) (int64, error) {
+	return c.IncrBy(ctx, key, -1)
+}
+
+// IncrBy adds delta to the int64 value by key, as a counter. If the
+// key does not exist it is created with value delta and no expiration.
+func (c *MemoryCache) IncrBy(ctx context.Context, key string, delta int64) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.incr(key, delta)
+}
+
+// incr adds delta to the counter stored at key, creating it through
+// the same maxEntries/evict/policy bookkeeping Set uses so counters
+// are subject to the same bound and are eligible as eviction
+// candidates. Caller must hold c.lock.
+func (c *MemoryCache) incr(key string, delta int64) (int64, error) {
+	item, ok := c.caches[key]
+	if !ok {
+		if c.maxEntries > 0 && len(c.caches) >= c.maxEntries {
+			if !c.evict(key) {
+				return 0, ErrAdmissionRejected
+			}
+		}
+
+		c.caches[key] = &CacheItem{Val: delta}
+		if c.policy != nil {
+			c.policy.Add(key)
+		}
+
+		return delta, nil
+	}
+
+	n, err := toInt64(item.Val)
+	if err != nil {
+		c.lastErr = err
+		return 0, err
+	}
+
+	n += delta
+	item.Val = n
+	if c.policy != nil {
+		c.policy.Add(key)
+	}
+
+	return n, nil
+}
+
+// toInt64 converts a cached value to int64 so it can be used as a
+// counter. float64 is accepted, with an integral-value check, since
+// JSONCodec - the default Codec for FileCache - decodes any numeric
+// Val back as float64.
+func toInt64(val interface{}) (int64, error) {
+	switch v := val.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, fmt.Errorf("cache: value is not an integer counter: %v", val)
+		}
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("cache: value is not an integer counter: %v", val)
+	}
+}
+
+// GetOrLoad returns the cached value for key if present - including a
+// cached nil, which Has reports as present even though Get alone
+// cannot tell it apart from a miss - otherwise it calls loader exactly
+// once even under concurrent misses for the same key - later callers
+// block on the in-flight call instead of racing to populate the cache
+// - caches the result for ttl, and returns it.
+func (c *MemoryCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if ok, err := c.Has(ctx, key); err != nil {
+		return nil, err
+	} else if ok {
+		return c.Get(ctx, key)
+	}
+
+	return c.loaders.Do(key, func() (interface{}, error) {
+		// another goroutine may have populated the cache while we were
+		// waiting to become the leader for key.
+		if ok, err := c.Has(ctx, key); err != nil {
+			return nil, err
+		} else if ok {
+			return c.Get(ctx, key)
+		}
+
+		val, err := loader(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = c.Set(ctx, key, val, ttl); err != nil {
+			return nil, err
+		}
+
+		return val, nil
+	})
+}
+
+// StartAndGC starts a background goroutine that walks the cache every
+// interval, removing entries whose Exp has passed. Call Stop to
+// terminate it. Calling StartAndGC again before Stop returns an error.
+func (c *MemoryCache) StartAndGC(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("cache: GC interval must be positive")
+	}
+
+	c.lock.Lock()
+	if c.stopGC != nil {
+		c.lock.Unlock()
+		return fmt.Errorf("cache: GC already started")
+	}
+	stop := make(chan struct{})
+	c.stopGC = stop
+	c.lock.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.gc()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop terminates the background GC goroutine started by StartAndGC.
+// It is a no-op if GC was never started.
+func (c *MemoryCache) Stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.stopGC != nil {
+		close(c.stopGC)
+		c.stopGC = nil
+	}
+}
+
+// gc removes all expired entries from the cache.
+func (c *MemoryCache) gc() {
+	now := time.Now().Unix()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key, item := range c.caches {
+		if item.Exp != 0 && item.Exp <= now {
+			delete(c.caches, key)
+			if c.policy != nil {
+				c.policy.Remove(key)
+			}
+		}
+	}
+}
+
+// Stats returns the cache's hit/miss/eviction counters.
+func (c *MemoryCache) Stats() Stats {
+	return Stats{
+		Hits:      atomic.LoadInt64(&c.hits),
+		Misses:    atomic.LoadInt64(&c.misses),
+		Evictions: atomic.LoadInt64(&c.evictions),
+	}
+}
+
 // Count cache item number
 func (c *MemoryCache) Count() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	return len(c.caches)
 }
 
 // LastErr get
 func (c *MemoryCache) LastErr() error {
 	return c.lastErr
-}
\ No newline at end of file
+}
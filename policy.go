@@ -0,0 +1,26 @@
+package cache
+
+// EvictionPolicy decides which key a bounded MemoryCache should evict
+// once it is full, and is kept informed of cache activity so it can
+// track recency, frequency, or both. Implementations must be safe for
+// concurrent use.
+type EvictionPolicy interface {
+	// Add records the insertion of a new key.
+	Add(key string)
+	// Touch records an access (hit) to an existing key.
+	Touch(key string)
+	// Remove forgets key, e.g. after a Del, expiry or eviction.
+	Remove(key string)
+	// Victim returns the key that should be evicted next, or "" if
+	// the policy currently holds no keys.
+	Victim() string
+}
+
+// AdmissionPolicy is implemented by eviction policies that may reject
+// a new candidate instead of always evicting the current Victim, e.g.
+// W-TinyLFU's frequency-based admission filter.
+type AdmissionPolicy interface {
+	// Admit reports whether candidate should be admitted in place of
+	// victim.
+	Admit(candidate, victim string) bool
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLRUPolicyVictim(t *testing.T) {
+	p := NewLRUPolicy()
+	p.Add("a")
+	p.Add("b")
+	p.Add("c")
+
+	if v := p.Victim(); v != "a" {
+		t.Fatalf("Victim() = %q, want %q", v, "a")
+	}
+
+	// touching "a" moves it to the front, so "b" becomes the victim.
+	p.Touch("a")
+	if v := p.Victim(); v != "b" {
+		t.Fatalf("Victim() after touch = %q, want %q", v, "b")
+	}
+
+	p.Remove("b")
+	if v := p.Victim(); v != "c" {
+		t.Fatalf("Victim() after remove = %q, want %q", v, "c")
+	}
+}
+
+func TestLRUPolicyEmpty(t *testing.T) {
+	p := NewLRUPolicy()
+	if v := p.Victim(); v != "" {
+		t.Fatalf("Victim() on empty policy = %q, want \"\"", v)
+	}
+}
+
+func TestLFUPolicyVictim(t *testing.T) {
+	p := NewLFUPolicy()
+	p.Add("a")
+	p.Add("b")
+	p.Add("c")
+
+	// bump "a" and "b" so "c" has the lowest frequency.
+	p.Touch("a")
+	p.Touch("a")
+	p.Touch("b")
+
+	if v := p.Victim(); v != "c" {
+		t.Fatalf("Victim() = %q, want %q", v, "c")
+	}
+
+	p.Remove("c")
+	if v := p.Victim(); v != "b" {
+		t.Fatalf("Victim() after remove = %q, want %q", v, "b")
+	}
+}
+
+func TestLFUPolicyEmpty(t *testing.T) {
+	p := NewLFUPolicy()
+	if v := p.Victim(); v != "" {
+		t.Fatalf("Victim() on empty policy = %q, want \"\"", v)
+	}
+}
+
+func TestTinyLFUPolicyAdmitPrefersFrequentCandidate(t *testing.T) {
+	p := NewTinyLFUPolicy()
+	p.Add("hot")
+	p.Add("cold")
+
+	// make "hot" strictly more frequent than "cold".
+	for i := 0; i < 5; i++ {
+		p.Touch("hot")
+	}
+
+	if !p.Admit("hot", "cold") {
+		t.Fatalf("Admit(hot, cold) = false, want true: hot is more frequent")
+	}
+	if p.Admit("cold", "hot") {
+		t.Fatalf("Admit(cold, hot) = true, want false: cold is less frequent than hot")
+	}
+}
+
+func TestMemoryCacheBoundedEvictsLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewBoundedMemoryCache(2, NewLRUPolicy())
+
+	must(t, c.Set(ctx, "a", 1, 0))
+	must(t, c.Set(ctx, "b", 2, 0))
+	must(t, c.Set(ctx, "c", 3, 0)) // evicts "a"
+
+	if v, _ := c.Get(ctx, "a"); v != nil {
+		t.Fatalf("Get(a) = %v, want nil: a should have been evicted", v)
+	}
+	if v, _ := c.Get(ctx, "b"); v != 2 {
+		t.Fatalf("Get(b) = %v, want 2", v)
+	}
+	if v, _ := c.Get(ctx, "c"); v != 3 {
+		t.Fatalf("Get(c) = %v, want 3", v)
+	}
+
+	stats := c.Stats()
+	if stats.Evictions != 1 {
+		t.Fatalf("Stats().Evictions = %d, want 1", stats.Evictions)
+	}
+}
+
+func TestMemoryCacheBoundedAdmissionRejected(t *testing.T) {
+	ctx := context.Background()
+	policy := NewTinyLFUPolicy()
+	c := NewBoundedMemoryCache(1, policy)
+
+	must(t, c.Set(ctx, "hot", 1, 0))
+	for i := 0; i < 10; i++ {
+		if _, err := c.Get(ctx, "hot"); err != nil {
+			t.Fatalf("Get(hot): %v", err)
+		}
+	}
+
+	err := c.Set(ctx, "cold", 2, 0)
+	if err != ErrAdmissionRejected {
+		t.Fatalf("Set(cold) error = %v, want ErrAdmissionRejected", err)
+	}
+
+	if v, _ := c.Get(ctx, "hot"); v != 1 {
+		t.Fatalf("Get(hot) = %v, want 1: rejected admission must not disturb existing entries", v)
+	}
+	if v, _ := c.Get(ctx, "cold"); v != nil {
+		t.Fatalf("Get(cold) = %v, want nil: rejected candidate must not be stored", v)
+	}
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
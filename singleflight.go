@@ -0,0 +1,50 @@
+package cache
+
+import "sync"
+
+// call is an in-flight or completed singleflightGroup call.
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// singleflightGroup suppresses duplicate concurrent work for the same
+// key: the first caller to arrive for a key runs fn, and any callers
+// that arrive while it is in flight block on the same call and share
+// its result, instead of each racing to do the work themselves. This
+// is the same mechanism golang.org/x/sync/singleflight uses to
+// collapse a cache stampede into a single load.
+type singleflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// Do executes fn for key, or waits for and returns the result of an
+// identical call already in flight for key.
+func (g *singleflightGroup) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+
+	c := new(call)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	c.val, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return c.val, c.err
+}
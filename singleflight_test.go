@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleflightGroupDoCoalesces(t *testing.T) {
+	var g singleflightGroup
+	var calls int64
+	var wg sync.WaitGroup
+
+	start := make(chan struct{})
+	results := make([]interface{}, 10)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			val, err := g.Do("key", func() (interface{}, error) {
+				atomic.AddInt64(&calls, 1)
+				time.Sleep(5 * time.Millisecond)
+				return 42, nil
+			})
+			if err != nil {
+				t.Errorf("Do: %v", err)
+			}
+			results[i] = val
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("fn called %d times, want 1", got)
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Fatalf("results[%d] = %v, want 42", i, v)
+		}
+	}
+}
+
+func TestSingleflightGroupDoSequential(t *testing.T) {
+	var g singleflightGroup
+	var calls int64
+
+	for i := 0; i < 3; i++ {
+		val, err := g.Do("key", func() (interface{}, error) {
+			atomic.AddInt64(&calls, 1)
+			return 7, nil
+		})
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		if val != 7 {
+			t.Fatalf("Do() = %v, want 7", val)
+		}
+	}
+
+	// calls made one after another, with no overlap, are not coalesced:
+	// each one's in-flight call has already been removed from g.calls.
+	if got := atomic.LoadInt64(&calls); got != 3 {
+		t.Fatalf("fn called %d times, want 3", got)
+	}
+}
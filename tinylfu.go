@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+const (
+	cmsDepth      = 4
+	cmsWidth      = 1024
+	cmsResetAfter = cmsWidth * 10
+)
+
+// countMinSketch is a small, lossy frequency counter: each key hashes
+// to one counter in every row, and Estimate returns the minimum of
+// the counters it hashes to, which never under-counts, except that
+// counters saturate at 255. Every counter is halved after cmsResetAfter additions so the sketch tracks recent
+// activity instead of accumulating forever.
+type countMinSketch struct {
+	rows [cmsDepth][cmsWidth]uint8
+	adds uint32
+}
+
+func (s *countMinSketch) indexes(key string) [cmsDepth]uint32 {
+	var idx [cmsDepth]uint32
+	for row := 0; row < cmsDepth; row++ {
+		h := fnv.New32a()
+		h.Write([]byte{byte(row)})
+		h.Write([]byte(key))
+		idx[row] = h.Sum32() % cmsWidth
+	}
+
+	return idx
+}
+
+// Add increases key's estimated frequency.
+func (s *countMinSketch) Add(key string) {
+	for row, col := range s.indexes(key) {
+		if s.rows[row][col] < 255 {
+			s.rows[row][col]++
+		}
+	}
+
+	s.adds++
+	if s.adds >= cmsResetAfter {
+		s.reset()
+	}
+}
+
+// reset halves every counter, decaying stale frequency estimates.
+func (s *countMinSketch) reset() {
+	for row := range s.rows {
+		for col := range s.rows[row] {
+			s.rows[row][col] /= 2
+		}
+	}
+
+	s.adds = 0
+}
+
+// Estimate returns key's estimated recent access frequency.
+func (s *countMinSketch) Estimate(key string) uint8 {
+	min := uint8(255)
+	for row, col := range s.indexes(key) {
+		if s.rows[row][col] < min {
+			min = s.rows[row][col]
+		}
+	}
+
+	return min
+}
+
+// TinyLFUPolicy is a W-TinyLFU admission filter layered over LRU
+// eviction order: recency decides the Victim, but Admit only accepts
+// a new candidate over that victim if the candidate's estimated
+// recent frequency is at least as high, so a burst of one-off keys
+// can't evict keys that are reused often.
+type TinyLFUPolicy struct {
+	mu     sync.Mutex
+	lru    *LRUPolicy
+	sketch countMinSketch
+}
+
+// NewTinyLFUPolicy creates an empty TinyLFUPolicy.
+func NewTinyLFUPolicy() *TinyLFUPolicy {
+	return &TinyLFUPolicy{lru: NewLRUPolicy()}
+}
+
+// Add records the insertion of a new key.
+func (p *TinyLFUPolicy) Add(key string) {
+	p.mu.Lock()
+	p.sketch.Add(key)
+	p.mu.Unlock()
+
+	p.lru.Add(key)
+}
+
+// Touch records an access to an existing key.
+func (p *TinyLFUPolicy) Touch(key string) {
+	p.mu.Lock()
+	p.sketch.Add(key)
+	p.mu.Unlock()
+
+	p.lru.Touch(key)
+}
+
+// Remove forgets key.
+func (p *TinyLFUPolicy) Remove(key string) {
+	p.lru.Remove(key)
+}
+
+// Victim returns the least recently used key, or "" if the policy
+// holds no keys.
+func (p *TinyLFUPolicy) Victim() string {
+	return p.lru.Victim()
+}
+
+// Admit reports whether candidate should be admitted over victim: it
+// is, unless victim's estimated frequency is strictly higher.
+func (p *TinyLFUPolicy) Admit(candidate, victim string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.sketch.Estimate(candidate) >= p.sketch.Estimate(victim)
+}